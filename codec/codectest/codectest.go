@@ -0,0 +1,146 @@
+// Package codectest checks codec.Coder implementations for conformance
+package codectest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-thor/thor/codec"
+)
+
+var (
+	// Garbage inputs fed to Unmarshal besides random bytes
+	Garbage = [][]byte{
+		{0x00},
+		{0xff, 0xff, 0xff, 0xff},
+		[]byte("{"),
+		[]byte("\"unterminated"),
+		[]byte("<?xml"),
+		{0x1f, 0x8b, 0x08, 0x00},
+	}
+
+	maxTruncations = 256
+	concurrency    = 8
+	iterations     = 50
+)
+
+// Run checks that c round-trips sample and its zero value, survives empty,
+// garbage and truncated input without panicking and is safe for concurrent
+// use. sample must be a value c can encode and reflect.DeepEqual can compare
+// once decoded, pointers are decoded into a new value of the pointed type.
+// Run it with -race to catch data races.
+func Run(t *testing.T, c codec.Coder, sample interface{}) {
+	t.Helper()
+
+	t.Run("String", func(t *testing.T) {
+		if c.String() == "" {
+			t.Fatal("String() is empty")
+		}
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		roundTrip(t, c, sample)
+		roundTrip(t, c, zero(sample))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		unmarshal(t, c, sample, nil)
+		unmarshal(t, c, sample, []byte{})
+	})
+
+	t.Run("Garbage", func(t *testing.T) {
+		for _, d := range Garbage {
+			unmarshal(t, c, sample, d)
+		}
+
+		r := rand.New(rand.NewSource(1))
+		for idx := 0; idx < 32; idx++ {
+			d := make([]byte, r.Intn(128)+1)
+			r.Read(d)
+			unmarshal(t, c, sample, d)
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		b, err := c.Marshal(sample)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		step := len(b)/maxTruncations + 1
+		for idx := 0; idx < len(b); idx += step {
+			unmarshal(t, c, sample, b[:idx])
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for idx := 0; idx < concurrency; idx++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < iterations; i++ {
+					roundTrip(t, c, sample)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func roundTrip(t *testing.T, c codec.Coder, v interface{}) {
+	defer recoverPanic(t, "round trip")
+
+	b, err := c.Marshal(v)
+	if err != nil {
+		t.Errorf("Marshal(%#v) error = %v", v, err)
+		return
+	}
+
+	target, decoded := newTarget(v)
+	if err = c.Unmarshal(b, target); err != nil {
+		t.Errorf("Unmarshal() of %#v error = %v", v, err)
+		return
+	}
+
+	if got := decoded(); !reflect.DeepEqual(got, v) {
+		t.Errorf("Unmarshal() = %#v, want %#v", got, v)
+	}
+}
+
+func unmarshal(t *testing.T, c codec.Coder, sample interface{}, d []byte) {
+	defer recoverPanic(t, fmt.Sprintf("Unmarshal(%q)", d))
+
+	target, _ := newTarget(sample)
+	_ = c.Unmarshal(d, target)
+}
+
+func recoverPanic(t *testing.T, what string) {
+	if r := recover(); r != nil {
+		t.Errorf("%s panicked: %v", what, r)
+	}
+}
+
+// newTarget returns a pointer to decode into and a func returning the decoded value shaped like v
+func newTarget(v interface{}) (interface{}, func() interface{}) {
+	typ := reflect.TypeOf(v)
+	if typ.Kind() == reflect.Ptr {
+		ptr := reflect.New(typ.Elem())
+		return ptr.Interface(), ptr.Interface
+	}
+
+	ptr := reflect.New(typ)
+	return ptr.Interface(), func() interface{} { return ptr.Elem().Interface() }
+}
+
+func zero(v interface{}) interface{} {
+	typ := reflect.TypeOf(v)
+	if typ.Kind() == reflect.Ptr {
+		return reflect.New(typ.Elem()).Interface()
+	}
+
+	return reflect.Zero(typ).Interface()
+}
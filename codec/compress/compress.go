@@ -0,0 +1,100 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+
+	"github.com/go-thor/thor/codec"
+)
+
+const (
+	None byte = iota // payload stored as is
+	Gzip             // payload compressed with gzip
+)
+
+type coder struct {
+	inner     codec.Coder
+	threshold int
+	limit     int
+}
+
+var (
+	DefaultLimit = 4 << 20
+
+	ErrPayloadTooShort  = errors.New("compress: payload too short")
+	ErrPayloadTooLarge  = errors.New("compress: payload too large")
+	ErrUnknownAlgorithm = errors.New("compress: unknown algorithm")
+)
+
+// NewCoder wraps c and gzips its output once it reaches threshold bytes, every
+// payload is prefixed with one byte naming the algorithm used. Only gzip is
+// supported. Unmarshal rejects payloads inflating beyond limit bytes, a limit
+// <= 0 falls back to DefaultLimit.
+func NewCoder(c codec.Coder, threshold, limit int) codec.Coder {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	return &coder{inner: c, threshold: threshold, limit: limit}
+}
+
+func (c coder) String() string {
+	return "compress+" + c.inner.String()
+}
+
+func (c coder) Marshal(v interface{}) ([]byte, error) {
+	b, err := c.inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) >= c.threshold {
+		buf := bytes.NewBuffer(make([]byte, 0, len(b)/2))
+		buf.WriteByte(Gzip)
+
+		w := gzip.NewWriter(buf)
+		if _, err = w.Write(b); err != nil {
+			return nil, err
+		}
+		if err = w.Close(); err != nil {
+			return nil, err
+		}
+
+		// keep the payload as is when gzip does not make it smaller
+		if buf.Len() < len(b)+1 {
+			return buf.Bytes(), nil
+		}
+	}
+
+	return append([]byte{None}, b...), nil
+}
+
+func (c coder) Unmarshal(d []byte, v interface{}) error {
+	if len(d) == 0 {
+		return ErrPayloadTooShort
+	}
+
+	switch d[0] {
+	case None:
+		return c.inner.Unmarshal(d[1:], v)
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(d[1:]))
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		b, err := io.ReadAll(io.LimitReader(r, int64(c.limit)+1))
+		if err != nil {
+			return err
+		} else if len(b) > c.limit {
+			return ErrPayloadTooLarge
+		}
+
+		return c.inner.Unmarshal(b, v)
+	default:
+		return ErrUnknownAlgorithm
+	}
+}
@@ -0,0 +1,149 @@
+package compress
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-thor/thor/codec/codectest"
+	"github.com/go-thor/thor/codec/json"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var (
+		large = map[string]interface{}{"name": strings.Repeat("thor", 256)}
+		size  = marshalSize(t, large)
+	)
+
+	tests := []struct {
+		name      string
+		threshold int
+		in        map[string]interface{}
+		flag      byte
+	}{
+		{"below threshold", size + 1, large, None},
+		{"at threshold", size, large, Gzip},
+		{"above threshold", size - 1, large, Gzip},
+		{"incompressible", 0, map[string]interface{}{"a": "b"}, None},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCoder(json.NewCoder(), tt.threshold, 0)
+
+			b, err := c.Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			if b[0] != tt.flag {
+				t.Fatalf("Marshal() flag = %d, want %d", b[0], tt.flag)
+			}
+
+			out := map[string]interface{}{}
+			if err = c.Unmarshal(b, &out); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(tt.in, out) {
+				t.Fatalf("Unmarshal() = %v, want %v", out, tt.in)
+			}
+		})
+	}
+}
+
+func TestCompresses(t *testing.T) {
+	var (
+		c  = NewCoder(json.NewCoder(), 0, 0)
+		in = strings.Repeat("thor", 1024)
+	)
+
+	b, err := c.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if len(b) >= len(in) {
+		t.Fatalf("Marshal() size = %d, want less than %d", len(b), len(in))
+	}
+}
+
+func TestLimit(t *testing.T) {
+	var (
+		in   = strings.Repeat("a", 1<<20)
+		size = marshalSize(t, in)
+	)
+
+	b, err := NewCoder(json.NewCoder(), 0, 0).Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if len(b) > size/100 {
+		t.Fatalf("Marshal() size = %d, want a highly compressed payload", len(b))
+	}
+
+	var out string
+	if err = NewCoder(json.NewCoder(), 0, size-1).Unmarshal(b, &out); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("Unmarshal() over limit error = %v, want %v", err, ErrPayloadTooLarge)
+	}
+
+	if err = NewCoder(json.NewCoder(), 0, size).Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal() at limit error = %v", err)
+	} else if out != in {
+		t.Fatal("Unmarshal() at limit returned a different value")
+	}
+}
+
+func TestString(t *testing.T) {
+	if got := NewCoder(json.NewCoder(), 0, 0).String(); got != "compress+json" {
+		t.Fatalf("String() = %q, want %q", got, "compress+json")
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	c := NewCoder(json.NewCoder(), 0, 0)
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, ErrPayloadTooShort},
+		{"unknown algorithm", []byte{0xff, '{', '}'}, ErrUnknownAlgorithm},
+		{"corrupt gzip", []byte{Gzip, 1, 2, 3}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			err := c.Unmarshal(tt.data, &v)
+			if err == nil {
+				t.Fatal("Unmarshal() error = nil")
+			}
+
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("Unmarshal() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func marshalSize(t *testing.T, v interface{}) int {
+	t.Helper()
+
+	b, err := json.NewCoder().Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	return len(b)
+}
+
+func TestConformance(t *testing.T) {
+	codectest.Run(t, NewCoder(json.NewCoder(), 16, 0), map[string]interface{}{
+		"name": "thor ⚡",
+		"tags": []interface{}{"a", "b"},
+	})
+}
@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/go-thor/thor/codec"
+)
+
+type coder struct {
+	inner codec.Coder
+	aead  cipher.AEAD
+}
+
+var (
+	ErrCiphertextTooShort = errors.New("crypto: ciphertext too short")
+)
+
+// NewCoder wraps c and seals its output with AES-GCM using the shared key,
+// the key must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+func NewCoder(c codec.Coder, key []byte) (codec.Coder, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coder{inner: c, aead: aead}, nil
+}
+
+func (c coder) String() string {
+	return "crypto+" + c.inner.String()
+}
+
+func (c coder) Marshal(v interface{}) ([]byte, error) {
+	b, err := c.inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, b, nil), nil
+}
+
+func (c coder) Unmarshal(d []byte, v interface{}) error {
+	size := c.aead.NonceSize()
+	if len(d) < size+c.aead.Overhead() {
+		return ErrCiphertextTooShort
+	}
+
+	b, err := c.aead.Open(nil, d[:size], d[size:], nil)
+	if err != nil {
+		return err
+	}
+
+	return c.inner.Unmarshal(b, v)
+}
@@ -0,0 +1,133 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-thor/thor/codec"
+	"github.com/go-thor/thor/codec/codectest"
+	"github.com/go-thor/thor/codec/json"
+)
+
+var key = []byte("0123456789abcdef0123456789abcdef")
+
+func newCoder(t *testing.T) codec.Coder {
+	t.Helper()
+
+	c, err := NewCoder(json.NewCoder(), key)
+	if err != nil {
+		t.Fatalf("NewCoder() error = %v", err)
+	}
+
+	return c
+}
+
+func TestNewCoderInvalidKey(t *testing.T) {
+	for _, size := range []int{0, 7, 15, 33} {
+		if _, err := NewCoder(json.NewCoder(), make([]byte, size)); err == nil {
+			t.Errorf("NewCoder() with %d byte key error = nil", size)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	if got := newCoder(t).String(); got != "crypto+json" {
+		t.Fatalf("String() = %q, want %q", got, "crypto+json")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var (
+		c    = newCoder(t)
+		in   = map[string]interface{}{"name": "thor", "port": float64(8080)}
+		want = map[string]interface{}{}
+	)
+
+	b, err := c.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if bytes.Contains(b, []byte("thor")) {
+		t.Fatalf("Marshal() output contains plaintext: %q", b)
+	}
+
+	if err = c.Unmarshal(b, &want); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(in, want) {
+		t.Fatalf("Unmarshal() = %v, want %v", want, in)
+	}
+}
+
+func TestNonceIsRandom(t *testing.T) {
+	c := newCoder(t)
+
+	b1, _ := c.Marshal("thor")
+	b2, _ := c.Marshal("thor")
+	if bytes.Equal(b1, b2) {
+		t.Fatal("Marshal() of the same value produced identical ciphertexts")
+	}
+}
+
+func TestTampered(t *testing.T) {
+	c := newCoder(t)
+
+	b, err := c.Marshal("thor")
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	for _, idx := range []int{0, len(b) / 2, len(b) - 1} {
+		d := append([]byte(nil), b...)
+		d[idx] ^= 1
+
+		var v string
+		if err = c.Unmarshal(d, &v); err == nil {
+			t.Errorf("Unmarshal() with byte %d flipped error = nil", idx)
+		}
+	}
+}
+
+func TestWrongKey(t *testing.T) {
+	b, err := newCoder(t).Marshal("thor")
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	other, err := NewCoder(json.NewCoder(), []byte("fedcba9876543210"))
+	if err != nil {
+		t.Fatalf("NewCoder() error = %v", err)
+	}
+
+	var v string
+	if err = other.Unmarshal(b, &v); err == nil {
+		t.Fatal("Unmarshal() with wrong key error = nil")
+	}
+}
+
+func TestTooShort(t *testing.T) {
+	c := newCoder(t)
+
+	b, err := c.Marshal("thor")
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	for _, d := range [][]byte{nil, b[:5], b[:12+16-1]} {
+		var v string
+		if err = c.Unmarshal(d, &v); !errors.Is(err, ErrCiphertextTooShort) {
+			t.Errorf("Unmarshal() of %d bytes error = %v, want %v", len(d), err, ErrCiphertextTooShort)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	codectest.Run(t, newCoder(t), map[string]interface{}{
+		"name": "thor ⚡",
+		"tags": []interface{}{"a", "b"},
+	})
+}
@@ -0,0 +1,23 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/go-thor/thor/codec/codectest"
+)
+
+type sample struct {
+	Name string
+	Port int
+	Tags []string
+	Meta map[string]string
+}
+
+func TestConformance(t *testing.T) {
+	codectest.Run(t, NewCoder(), sample{
+		Name: "thor ⚡",
+		Port: 8080,
+		Tags: []string{"a", "b"},
+		Meta: map[string]string{"env": "prod"},
+	})
+}
@@ -1,6 +1,8 @@
 package thor
 
 import (
+	"context"
+
 	"github.com/go-thor/thor/logger"
 	"github.com/go-thor/thor/server"
 )
@@ -8,8 +10,12 @@ import (
 type (
 	// Options new server options
 	Options struct {
+		ctx             context.Context
 		startupTimeout  int
 		shutdownTimeout int
+		banner          bool
+		beforeShutdown  []func(ctx context.Context) error
+		afterShutdown   []func(ctx context.Context) error
 		log             logger.Logger
 		servers         []server.Server
 	}
@@ -18,6 +24,11 @@ type (
 	Option func(ops *Options)
 )
 
+// WithContext app context, cancel it to abort startup or trigger shutdown
+func WithContext(ctx context.Context) Option {
+	return func(o *Options) { o.ctx = ctx }
+}
+
 // WithLog with service id.
 func WithLog(l logger.Logger) Option {
 	return func(o *Options) { o.log = l }
@@ -28,11 +39,26 @@ func WithStartupTimeout(timeout int) Option {
 	return func(ops *Options) { ops.startupTimeout = timeout }
 }
 
-// WithShutdownTimeout app shutdown timeout
+// WithShutdownTimeout app shutdown timeout, bounds the whole sequence including shutdown hooks
 func WithShutdownTimeout(timeout int) Option {
 	return func(ops *Options) { ops.shutdownTimeout = timeout }
 }
 
+// WithBanner log a startup summary before serve
+func WithBanner(banner bool) Option {
+	return func(ops *Options) { ops.banner = banner }
+}
+
+// WithBeforeShutdown run before any server shutdown, like deregister from discovery
+func WithBeforeShutdown(fns ...func(ctx context.Context) error) Option {
+	return func(ops *Options) { ops.beforeShutdown = append(ops.beforeShutdown, fns...) }
+}
+
+// WithAfterShutdown run after all servers shutdown, like flush metrics and traces
+func WithAfterShutdown(fns ...func(ctx context.Context) error) Option {
+	return func(ops *Options) { ops.afterShutdown = append(ops.afterShutdown, fns...) }
+}
+
 // WithServer set servers
 func WithServer(boxes ...server.Server) Option {
 	return func(ops *Options) { ops.servers = boxes }
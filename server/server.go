@@ -17,4 +17,9 @@ type (
 		BeforeShutdown(ctx context.Context) error
 		AfterShutdown(ctx context.Context) error
 	}
+
+	// Checker self-check before serve, like port availability, cert expiry
+	Checker interface {
+		Check(ctx context.Context) error
+	}
 )
@@ -2,8 +2,10 @@ package thor
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -19,13 +21,15 @@ type (
 		Name() string
 		Version() string
 		Namespace() string
+		Ready() bool // ready once every server is serving, not ready again once a server stops or shutdown starts
 		Run() error
 	}
 
 	// application app interface
 	application struct {
-		opts *Options
-		quit chan os.Signal
+		opts  *Options
+		quit  chan os.Signal
+		state atomic.Int32
 	}
 
 	serverErr struct {
@@ -34,6 +38,12 @@ type (
 	}
 )
 
+const (
+	stateStarting int32 = iota
+	stateReady
+	stateDown
+)
+
 // New a server bootstrap
 func New(options ...Option) Application {
 	opts := &Options{}
@@ -41,6 +51,9 @@ func New(options ...Option) Application {
 		opt(opts)
 	}
 
+	if opts.ctx == nil {
+		opts.ctx = context.Background()
+	}
 	if opts.startupTimeout == 0 {
 		opts.startupTimeout = 1000
 	}
@@ -52,7 +65,7 @@ func New(options ...Option) Application {
 	}
 
 	app := &application{
-		quit: make(chan os.Signal),
+		quit: make(chan os.Signal, 1),
 		opts: opts,
 	}
 
@@ -62,7 +75,7 @@ func New(options ...Option) Application {
 }
 
 func (app *application) ID() string {
-	return build.ID
+	return build.Instance
 }
 
 func (app *application) Name() string {
@@ -77,13 +90,25 @@ func (app *application) Namespace() string {
 	return build.Namespace
 }
 
+func (app *application) Ready() bool {
+	return app.state.Load() == stateReady
+}
+
 func (app *application) Run() error {
+	// signals stay captured until shutdown finishes, so a second one can not kill the process mid-shutdown
+	defer func() {
+		signal.Stop(app.quit)
+		close(app.quit)
+	}()
+
 	if err := app.serve(); err != nil {
 		return err
 	}
 
-	defer close(app.quit)
-	<-app.quit
+	select {
+	case <-app.quit:
+	case <-app.opts.ctx.Done():
+	}
 
 	if err := app.shutdown(); err != nil {
 		return err
@@ -95,13 +120,22 @@ func (app *application) Run() error {
 func (app *application) serve() error {
 	app.opts.log.Info("serve start...")
 
+	if app.opts.banner {
+		app.banner()
+	}
+
+	if err := app.check(); err != nil {
+		app.opts.log.Errorf("serve error: %v", err)
+		return err
+	}
+
 	g := errgroup.Group{}
 	for _, b := range app.opts.servers {
 		b := b
 		g.Go(func() error {
 			var (
 				hook        server.Hook
-				ctx, cancel = context.WithTimeout(context.Background(), time.Duration(app.opts.startupTimeout)*time.Millisecond)
+				ctx, cancel = context.WithTimeout(app.opts.ctx, time.Duration(app.opts.startupTimeout)*time.Millisecond)
 			)
 			defer cancel()
 
@@ -132,17 +166,62 @@ func (app *application) serve() error {
 	err := g.Wait()
 	if err != nil {
 		app.opts.log.Errorf("serve error: %v", err)
-	} else {
+	} else if app.state.CompareAndSwap(stateStarting, stateReady) {
 		app.opts.log.Infof("serve done...")
 	}
 
 	return err
 }
 
+func (app *application) banner() {
+	names := make([]string, len(app.opts.servers))
+	for idx, b := range app.opts.servers {
+		names[idx] = b.Name()
+	}
+
+	app.opts.log.Infow("startup",
+		"namespace", build.Namespace,
+		"name", build.Name,
+		"version", build.Version,
+		"instance", build.Instance,
+		"build_id", build.BuildId,
+		"build_time", build.BuildTime,
+		"servers", names,
+		"startup_timeout", app.opts.startupTimeout,
+		"shutdown_timeout", app.opts.shutdownTimeout,
+	)
+}
+
+// check runs every server.Checker, the first failure cancels the others
+func (app *application) check() error {
+	g, gctx := errgroup.WithContext(app.opts.ctx)
+	for _, b := range app.opts.servers {
+		b := b
+		checker, ok := b.(server.Checker)
+		if !ok {
+			continue
+		}
+
+		g.Go(func() error {
+			ctx, cancel := context.WithTimeout(gctx, time.Duration(app.opts.startupTimeout)*time.Millisecond)
+			defer cancel()
+
+			app.opts.log.Infof("check %s", b.Name())
+			if err := checker.Check(ctx); err != nil {
+				return fmt.Errorf("check %s: %w", b.Name(), err)
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
 func (app *application) startServer(ctx context.Context, b server.Server) error {
 	var (
 		serv    server.Server
-		serveCh = make(chan serverErr)
+		serveCh = make(chan serverErr, 1)
 	)
 
 	if v, ok := b.(server.Server); ok {
@@ -174,60 +253,119 @@ func (app *application) startServer(ctx context.Context, b server.Server) error
 		}
 	}
 
+	go app.watchServer(serveCh)
+
 	return nil
 }
 
+// watchServer marks the app not ready once a server that outlived startup stops serving
+func (app *application) watchServer(serveCh <-chan serverErr) {
+	ch, ok := <-serveCh
+	if !ok || app.state.Swap(stateDown) == stateDown {
+		return
+	}
+
+	if ch.err != nil {
+		app.opts.log.Errorf("serve %s error: %v", ch.server.Name(), ch.err)
+	} else {
+		app.opts.log.Errorf("serve %s stopped", ch.server.Name())
+	}
+}
+
 func (app *application) shutdown() error {
+	app.state.Store(stateDown)
 	app.opts.log.Info("shutdown start...")
 
+	// one deadline bounds the whole sequence, every phase still runs and the first error wins
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(app.opts.shutdownTimeout)*time.Millisecond)
+	defer cancel()
+
+	err := app.shutdownHooks(ctx, app.opts.beforeShutdown)
+	if serversErr := app.shutdownServers(ctx); err == nil {
+		err = serversErr
+	}
+	if afterErr := app.shutdownHooks(ctx, app.opts.afterShutdown); err == nil {
+		err = afterErr
+	}
+
+	if err != nil {
+		app.opts.log.Errorf("shutdown error: %v", err)
+	} else {
+		app.opts.log.Infof("shutdown done...")
+	}
+
+	return err
+}
+
+func (app *application) shutdownHooks(ctx context.Context, fns []func(ctx context.Context) error) error {
+	var first error
+	for _, fn := range fns {
+		if err := app.shutdownHook(ctx, fn); err != nil {
+			app.opts.log.Errorf("shutdown hook error: %v", err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+
+	return first
+}
+
+// shutdownHook returns once fn does or ctx is done, so a hook ignoring ctx can not block shutdown
+func (app *application) shutdownHook(ctx context.Context, fn func(ctx context.Context) error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn(ctx)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (app *application) shutdownServers(ctx context.Context) error {
 	g := errgroup.Group{}
 	for _, b := range app.opts.servers {
 		b := b
 		g.Go(func() error {
 			var (
-				hook        server.Hook
-				ctx, cancel = context.WithTimeout(context.Background(), time.Duration(app.opts.shutdownTimeout)*time.Millisecond)
+				hook  server.Hook
+				first error
+				keep  = func(err error) {
+					if first == nil {
+						first = err
+					}
+				}
 			)
-			defer cancel()
 
 			if v, ok := b.(server.Hook); ok {
 				hook = v
 			}
 
 			if hook != nil {
-				if err := hook.BeforeShutdown(ctx); err != nil {
-					return err
-				}
+				keep(app.shutdownHook(ctx, hook.BeforeShutdown))
 			}
 
-			if err := app.shutdownServer(ctx, b); err != nil {
-				return err
-			}
+			keep(app.shutdownServer(ctx, b))
 
 			if hook != nil {
-				if err := hook.AfterShutdown(ctx); err != nil {
-					return err
-				}
+				keep(app.shutdownHook(ctx, hook.AfterShutdown))
 			}
 
-			return nil
+			return first
 		})
 	}
 
-	err := g.Wait()
-	if err != nil {
-		app.opts.log.Errorf("shutdown error: %v", err)
-	} else {
-		app.opts.log.Infof("shutdown done...")
-	}
-
-	return err
+	return g.Wait()
 }
 
 func (app *application) shutdownServer(ctx context.Context, b server.Server) error {
 	var (
 		serv       server.Server
-		shutdownCh = make(chan serverErr)
+		shutdownCh = make(chan serverErr, 1)
 	)
 
 	if v, ok := b.(server.Server); ok {
@@ -0,0 +1,543 @@
+package thor
+
+import (
+	"context"
+	"errors"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-thor/thor/build"
+	"github.com/go-thor/thor/logger"
+)
+
+type (
+	recorder struct {
+		sync.Mutex
+		events    []string
+		deadlines []time.Time
+	}
+
+	fakeServer struct {
+		rec         *recorder
+		name        string
+		stop        chan struct{}
+		serveErr    chan error
+		serveCtx    context.Context
+		shutdownErr error
+		onShutdown  func()
+	}
+
+	hookServer struct {
+		*fakeServer
+		beforeErr error
+		afterErr  error
+	}
+
+	checkServer struct {
+		*fakeServer
+		check func(ctx context.Context) error
+	}
+
+	bannerLogger struct {
+		logger.Logger
+		sync.Mutex
+		msg           string
+		keysAndValues []interface{}
+	}
+)
+
+func (s *checkServer) Check(ctx context.Context) error { return s.check(ctx) }
+
+func (l *bannerLogger) Infow(msg string, keysAndValues ...interface{}) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.msg, l.keysAndValues = msg, keysAndValues
+}
+
+func (r *recorder) record(ctx context.Context, event string) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.events = append(r.events, event)
+	if d, ok := ctx.Deadline(); ok {
+		r.deadlines = append(r.deadlines, d)
+	}
+}
+
+func (r *recorder) hook(event string, err error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		r.record(ctx, event)
+		return err
+	}
+}
+
+func (r *recorder) snapshot() []string {
+	r.Lock()
+	defer r.Unlock()
+
+	return append([]string(nil), r.events...)
+}
+
+func newFakeServer(rec *recorder, name string) *fakeServer {
+	return &fakeServer{rec: rec, name: name, stop: make(chan struct{}), serveErr: make(chan error, 1)}
+}
+
+func (s *fakeServer) Name() string { return s.name }
+
+func (s *fakeServer) Serve(ctx context.Context) error {
+	s.rec.record(ctx, s.name+".Serve")
+	s.rec.Lock()
+	s.serveCtx = ctx
+	s.rec.Unlock()
+
+	select {
+	case <-s.stop:
+		return nil
+	case err := <-s.serveErr:
+		return err
+	}
+}
+
+func (s *fakeServer) Shutdown(ctx context.Context) error {
+	s.rec.record(ctx, s.name+".Shutdown")
+	if s.onShutdown != nil {
+		s.onShutdown()
+	}
+	close(s.stop)
+
+	return s.shutdownErr
+}
+
+func (s *hookServer) BeforeServe(ctx context.Context) error { return nil }
+func (s *hookServer) AfterServe(ctx context.Context) error  { return nil }
+
+func (s *hookServer) BeforeShutdown(ctx context.Context) error {
+	s.rec.record(ctx, s.name+".BeforeShutdown")
+	return s.beforeErr
+}
+
+func (s *hookServer) AfterShutdown(ctx context.Context) error {
+	s.rec.record(ctx, s.name+".AfterShutdown")
+	return s.afterErr
+}
+
+func newApp(t *testing.T, opts ...Option) *application {
+	t.Helper()
+
+	app := New(append([]Option{WithStartupTimeout(10)}, opts...)...).(*application)
+	t.Cleanup(func() { signal.Stop(app.quit) })
+
+	return app
+}
+
+// runAsync runs app in the background, the returned channel yields the Run error
+func runAsync(app *application) <-chan error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- app.Run() }()
+
+	return errCh
+}
+
+func wait(t *testing.T, errCh <-chan error) error {
+	t.Helper()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return within 1s")
+		return nil
+	}
+}
+
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+
+	t.Fatal("condition not met within 1s")
+}
+
+func TestReady(t *testing.T) {
+	var (
+		serv            = newFakeServer(&recorder{}, "demo")
+		app             = newApp(t, WithServer(serv))
+		readyOnShutdown = true
+	)
+	serv.onShutdown = func() { readyOnShutdown = app.Ready() }
+
+	if app.Ready() {
+		t.Fatal("Ready() before serve = true")
+	}
+
+	if err := app.serve(); err != nil {
+		t.Fatalf("serve() error = %v", err)
+	}
+
+	if !app.Ready() {
+		t.Fatal("Ready() after serve = false")
+	}
+
+	if err := app.shutdown(); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+
+	if readyOnShutdown {
+		t.Fatal("Ready() during shutdown = true")
+	}
+
+	if app.Ready() {
+		t.Fatal("Ready() after shutdown = true")
+	}
+}
+
+func TestReadyServerDies(t *testing.T) {
+	var (
+		serv = newFakeServer(&recorder{}, "demo")
+		app  = newApp(t, WithServer(serv))
+	)
+
+	if err := app.serve(); err != nil {
+		t.Fatalf("serve() error = %v", err)
+	}
+
+	if !app.Ready() {
+		t.Fatal("Ready() after serve = false")
+	}
+
+	serv.serveErr <- errors.New("listener closed")
+	eventually(t, func() bool { return !app.Ready() })
+}
+
+func TestRunContextCancel(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		rec         = &recorder{}
+		app         = newApp(t, WithContext(ctx), WithServer(newFakeServer(rec, "demo")))
+		errCh       = runAsync(app)
+	)
+	defer cancel()
+
+	eventually(t, app.Ready)
+	cancel()
+
+	if err := wait(t, errCh); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got, want := rec.snapshot(), []string{"demo.Serve", "demo.Shutdown"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+}
+
+func TestRunContextCancelDuringStartup(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		serv        = newFakeServer(&recorder{}, "demo")
+		app         = newApp(t, WithContext(ctx), WithStartupTimeout(5000), WithServer(serv))
+		errCh       = runAsync(app)
+	)
+
+	eventually(t, func() bool {
+		serv.rec.Lock()
+		defer serv.rec.Unlock()
+		return serv.serveCtx != nil
+	})
+	cancel()
+
+	if err := wait(t, errCh); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want %v", err, context.Canceled)
+	}
+
+	serv.rec.Lock()
+	defer serv.rec.Unlock()
+	if serv.serveCtx.Err() == nil {
+		t.Fatal("Serve() context not cancelled")
+	}
+}
+
+func TestRunSecondSignalDuringShutdown(t *testing.T) {
+	var (
+		rec      = &recorder{}
+		serv     = newFakeServer(rec, "demo")
+		app      = newApp(t, WithServer(serv))
+		captured bool
+	)
+	serv.onShutdown = func() {
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		for deadline := time.Now().Add(time.Second); !captured && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			captured = len(app.quit) == 1
+		}
+	}
+
+	errCh := runAsync(app)
+	eventually(t, app.Ready)
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+
+	if err := wait(t, errCh); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if !captured {
+		t.Fatal("second signal during shutdown was not captured")
+	}
+}
+
+func TestCheckFailFast(t *testing.T) {
+	var (
+		wantErr = errors.New("port in use")
+		slowErr = make(chan error, 1)
+		bad     = &checkServer{
+			fakeServer: newFakeServer(&recorder{}, "bad"),
+			check:      func(ctx context.Context) error { return wantErr },
+		}
+		slow = &checkServer{
+			fakeServer: newFakeServer(&recorder{}, "slow"),
+			check: func(ctx context.Context) error {
+				<-ctx.Done()
+				slowErr <- ctx.Err()
+				return ctx.Err()
+			},
+		}
+		app   = newApp(t, WithStartupTimeout(5000), WithServer(slow, bad))
+		start = time.Now()
+	)
+
+	err := app.check()
+	if !errors.Is(err, wantErr) || err.Error() != "check bad: port in use" {
+		t.Fatalf("check() error = %v, want check bad: %v", err, wantErr)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("check() took %v, want fail fast", elapsed)
+	}
+
+	if err = <-slowErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("slow check context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRunCheckError(t *testing.T) {
+	var (
+		rec     = &recorder{}
+		wantErr = errors.New("certificate expired")
+		bad     = &checkServer{
+			fakeServer: newFakeServer(rec, "bad"),
+			check:      func(ctx context.Context) error { return wantErr },
+		}
+		app = newApp(t, WithServer(newFakeServer(rec, "good"), bad))
+	)
+
+	err := wait(t, runAsync(app))
+	if !errors.Is(err, wantErr) || err.Error() != "check bad: certificate expired" {
+		t.Fatalf("Run() error = %v, want check bad: %v", err, wantErr)
+	}
+
+	if got := rec.snapshot(); len(got) != 0 {
+		t.Fatalf("events = %v, want no server started", got)
+	}
+
+	if app.Ready() {
+		t.Fatal("Ready() after failed check = true")
+	}
+}
+
+func TestBanner(t *testing.T) {
+	for _, banner := range []bool{false, true} {
+		var (
+			log = &bannerLogger{Logger: logger.Nop}
+			app = newApp(t, WithLog(log), WithBanner(banner), WithServer(newFakeServer(&recorder{}, "demo")))
+		)
+
+		if err := app.serve(); err != nil {
+			t.Fatalf("serve() error = %v", err)
+		}
+		if err := app.shutdown(); err != nil {
+			t.Fatalf("shutdown() error = %v", err)
+		}
+
+		log.Lock()
+		if !banner {
+			if log.msg != "" {
+				t.Fatalf("banner logged without WithBanner: %s %v", log.msg, log.keysAndValues)
+			}
+			log.Unlock()
+			continue
+		}
+
+		if log.msg != "startup" {
+			t.Fatalf("banner message = %q, want %q", log.msg, "startup")
+		}
+
+		fields := map[string]interface{}{}
+		for idx := 0; idx+1 < len(log.keysAndValues); idx += 2 {
+			fields[log.keysAndValues[idx].(string)] = log.keysAndValues[idx+1]
+		}
+		log.Unlock()
+
+		want := map[string]interface{}{
+			"namespace":        build.Namespace,
+			"name":             build.Name,
+			"version":          build.Version,
+			"instance":         build.Instance,
+			"build_id":         build.BuildId,
+			"build_time":       build.BuildTime,
+			"servers":          []string{"demo"},
+			"startup_timeout":  10,
+			"shutdown_timeout": 5000,
+		}
+		if !reflect.DeepEqual(fields, want) {
+			t.Fatalf("banner fields = %v, want %v", fields, want)
+		}
+	}
+}
+
+// serveAndShutdown serves app and returns the shutdown error, with the Serve events dropped
+func serveAndShutdown(t *testing.T, app *application, rec *recorder) error {
+	t.Helper()
+
+	if err := app.serve(); err != nil {
+		t.Fatalf("serve() error = %v", err)
+	}
+
+	rec.Lock()
+	rec.events, rec.deadlines = nil, nil
+	rec.Unlock()
+
+	return app.shutdown()
+}
+
+func TestShutdownOrder(t *testing.T) {
+	var (
+		rec = &recorder{}
+		app = newApp(t,
+			WithServer(&hookServer{fakeServer: newFakeServer(rec, "demo")}),
+			WithBeforeShutdown(rec.hook("before", nil)),
+			WithAfterShutdown(rec.hook("after", nil)),
+		)
+	)
+
+	if err := serveAndShutdown(t, app, rec); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+
+	want := []string{"before", "demo.BeforeShutdown", "demo.Shutdown", "demo.AfterShutdown", "after"}
+	if got := rec.snapshot(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+}
+
+func TestShutdownErrors(t *testing.T) {
+	var (
+		errBefore       = errors.New("deregister failed")
+		errAfter        = errors.New("flush failed")
+		errServer       = errors.New("shutdown failed")
+		errServerBefore = errors.New("drain failed")
+		errServerAfter  = errors.New("close failed")
+		all             = []string{"before", "demo.BeforeShutdown", "demo.Shutdown", "demo.AfterShutdown", "after1", "after2"}
+	)
+
+	tests := []struct {
+		name         string
+		before       error
+		after        error
+		shutdown     error
+		serverBefore error
+		serverAfter  error
+		want         error
+	}{
+		{"before hook", errBefore, nil, nil, nil, nil, errBefore},
+		{"after hook", nil, errAfter, nil, nil, nil, errAfter},
+		{"server shutdown", nil, nil, errServer, nil, nil, errServer},
+		{"server before shutdown", nil, nil, nil, errServerBefore, nil, errServerBefore},
+		{"server after shutdown", nil, nil, nil, nil, errServerAfter, errServerAfter},
+		{"first error wins", errBefore, errAfter, errServer, errServerBefore, errServerAfter, errBefore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				rec  = &recorder{}
+				serv = &hookServer{fakeServer: newFakeServer(rec, "demo"), beforeErr: tt.serverBefore, afterErr: tt.serverAfter}
+			)
+			serv.shutdownErr = tt.shutdown
+
+			app := newApp(t,
+				WithServer(serv),
+				WithBeforeShutdown(rec.hook("before", tt.before)),
+				WithAfterShutdown(rec.hook("after1", tt.after), rec.hook("after2", errors.New("later"))),
+			)
+
+			if err := serveAndShutdown(t, app, rec); !errors.Is(err, tt.want) {
+				t.Fatalf("shutdown() error = %v, want %v", err, tt.want)
+			}
+
+			if got := rec.snapshot(); !reflect.DeepEqual(got, all) {
+				t.Fatalf("events = %v, want %v", got, all)
+			}
+		})
+	}
+}
+
+func TestShutdownSingleDeadline(t *testing.T) {
+	var (
+		rec = &recorder{}
+		app = newApp(t,
+			WithShutdownTimeout(1000),
+			WithServer(&hookServer{fakeServer: newFakeServer(rec, "demo")}),
+			WithBeforeShutdown(rec.hook("before", nil)),
+			WithAfterShutdown(rec.hook("after", nil)),
+		)
+	)
+
+	if err := serveAndShutdown(t, app, rec); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+
+	rec.Lock()
+	defer rec.Unlock()
+	if len(rec.deadlines) != len(rec.events) {
+		t.Fatalf("got %d deadlines for %d events", len(rec.deadlines), len(rec.events))
+	}
+	for _, d := range rec.deadlines {
+		if !d.Equal(rec.deadlines[0]) {
+			t.Fatalf("deadlines = %v, want one shared deadline", rec.deadlines)
+		}
+	}
+}
+
+func TestShutdownHookTimeout(t *testing.T) {
+	var (
+		rec     = &recorder{}
+		block   = make(chan struct{})
+		blocked = func(ctx context.Context) error {
+			<-block
+			return nil
+		}
+		app = newApp(t,
+			WithShutdownTimeout(50),
+			WithServer(newFakeServer(rec, "demo")),
+			WithBeforeShutdown(blocked),
+			WithAfterShutdown(blocked),
+		)
+		start = time.Now()
+	)
+	defer close(block)
+
+	if err := serveAndShutdown(t, app, rec); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("shutdown() took %v, want it bounded by the shutdown timeout", elapsed)
+	}
+}